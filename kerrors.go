@@ -1,10 +1,17 @@
 package kerrors
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"iter"
+	"log/slog"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type (
@@ -60,12 +67,120 @@ func JSONValue(err error) any {
 	}
 }
 
+// errShortString renders the short message chain of an error: the messages of
+// [*Error] causes joined by ": ", skipping over stack traces, with any other
+// error contributing its Error() string. Kinds are not included.
+func errShortString(err error) string {
+	if err == nil {
+		return ""
+	}
+	switch k := err.(type) {
+	case *Error:
+		cause := k.Cause()
+		if st, ok := cause.(*StackTrace); ok && st.Cause() == nil {
+			return k.message
+		}
+		if s := errShortString(cause); s != "" {
+			return k.message + ": " + s
+		}
+		return k.message
+	case *StackTrace:
+		if s := errShortString(k.Cause()); s != "" {
+			return s
+		}
+		return k.Error()
+	default:
+		return err.Error()
+	}
+}
+
+// writeErrorVerbose writes the full chain of causes one error per line,
+// walking causes the same way [JSONValue] does, and prints every frame of any
+// [*StackTrace] encountered along the way. The kind of an [*Error] is not
+// printed.
+func writeErrorVerbose(w io.Writer, err error) {
+	if err == nil {
+		return
+	}
+	switch k := err.(type) {
+	case *Error:
+		io.WriteString(w, k.message)
+		if cause := k.Cause(); cause != nil {
+			io.WriteString(w, "\n")
+			writeErrorVerbose(w, cause)
+		}
+	case *StackTrace:
+		io.WriteString(w, "Stack trace:")
+		for _, f := range k.StackTrace() {
+			io.WriteString(w, "\n")
+			fmt.Fprintf(w, "%+v", f)
+		}
+		if cause := k.Cause(); cause != nil {
+			io.WriteString(w, "\n")
+			writeErrorVerbose(w, cause)
+		}
+	case errorUnwrapper:
+		io.WriteString(w, err.Error())
+		for _, i := range k.Unwrap() {
+			io.WriteString(w, "\n")
+			writeErrorVerbose(w, i)
+		}
+	case errorSingleUnwrapper:
+		io.WriteString(w, err.Error())
+		if cause := k.Unwrap(); cause != nil {
+			io.WriteString(w, "\n")
+			writeErrorVerbose(w, cause)
+		}
+	default:
+		io.WriteString(w, err.Error())
+	}
+}
+
+// errSlogValue resolves an error to a [slog.Value], walking the same shape
+// as [JSONValue] but producing slog attributes instead of a json tree
+func errSlogValue(err error) slog.Value {
+	if err == nil {
+		return slog.Value{}
+	}
+	switch k := err.(type) {
+	case slog.LogValuer:
+		return k.LogValue()
+	case errorUnwrapper:
+		errs := k.Unwrap()
+		cause := make([]slog.Attr, 0, len(errs))
+		for n, i := range errs {
+			cause = append(cause, slog.Attr{Key: strconv.Itoa(n), Value: errSlogValue(i)})
+		}
+		return slog.GroupValue(
+			slog.String("msg", err.Error()),
+			slog.Attr{Key: "cause", Value: slog.GroupValue(cause...)},
+		)
+	case errorSingleUnwrapper:
+		return slog.GroupValue(
+			slog.String("msg", err.Error()),
+			slog.Any("cause", errSlogValue(k.Unwrap())),
+		)
+	default:
+		return slog.StringValue(err.Error())
+	}
+}
+
+// KErrorAttr returns a [slog.Attr] for err under the conventional "err" key,
+// for use by terminal log handlers that do not automatically resolve
+// [slog.LogValuer]
+func KErrorAttr(err error) slog.Attr {
+	return slog.Attr{Key: "err", Value: errSlogValue(err)}
+}
+
 type (
 	// Error is an error with context
 	Error struct {
-		message string
-		wrapped [2]error
-		skip    int
+		message  string
+		wrapped  [2]error
+		skip     int
+		fields   map[string]any
+		retry    *RetryHint
+		hasStack bool
 	}
 
 	// ErrorOpt is an options function used by [New]
@@ -81,6 +196,10 @@ func New(opts ...ErrorOpt) error {
 		i(e)
 	}
 	e.wrapped[1] = AddStackTrace(e.wrapped[1], 1+e.skip)
+	// AddStackTrace guarantees a stack trace is now present somewhere in
+	// e.wrapped[1]'s chain, so this error is always stack-aware, letting any
+	// error wrapping this one skip straight past the O(depth) [Find] walk
+	e.hasStack = true
 	return e
 }
 
@@ -89,6 +208,48 @@ func (e *Error) Error() string {
 	return e.message
 }
 
+// Format implements [fmt.Formatter]. %s and %v print the short message
+// chain, %+v additionally walks the full chain of causes printing every frame
+// of any [*StackTrace] encountered, and %q quotes the short form.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeErrorVerbose(s, e)
+			return
+		}
+		io.WriteString(s, errShortString(e))
+	case 's':
+		io.WriteString(s, errShortString(e))
+	case 'q':
+		fmt.Fprintf(s, "%q", errShortString(e))
+	}
+}
+
+// LogValue implements [slog.LogValuer] and returns a structured
+// representation of the error for structured logging
+func (e *Error) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 6)
+	attrs = append(attrs, slog.String("msg", e.Error()))
+	if kind := e.Kind(); kind != nil {
+		attrs = append(attrs, slog.Attr{Key: "kind", Value: errSlogValue(kind)})
+	}
+	if cause := e.Cause(); cause != nil {
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: errSlogValue(cause)})
+	}
+	if len(e.fields) > 0 {
+		fieldAttrs := make([]slog.Attr, 0, len(e.fields))
+		for k, v := range e.fields {
+			fieldAttrs = append(fieldAttrs, slog.Any(k, v))
+		}
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fieldAttrs...)})
+	}
+	if e.retry != nil {
+		attrs = append(attrs, slog.Attr{Key: "retry", Value: e.retry.logValue()})
+	}
+	return slog.GroupValue(attrs...)
+}
+
 // Unwrap implements [errors.Unwrap]
 func (e *Error) Unwrap() []error {
 	start := 0
@@ -138,21 +299,163 @@ func OptSkip(skip int) ErrorOpt {
 	}
 }
 
+// OptField returns an [ErrorOpt] that attaches a structured field to [Error]
+func OptField(key string, val any) ErrorOpt {
+	return func(e *Error) {
+		if e.fields == nil {
+			e.fields = map[string]any{}
+		}
+		e.fields[key] = val
+	}
+}
+
+// OptFields returns an [ErrorOpt] that attaches structured fields to [Error]
+func OptFields(fields map[string]any) ErrorOpt {
+	return func(e *Error) {
+		if len(fields) == 0 {
+			return
+		}
+		if e.fields == nil {
+			e.fields = make(map[string]any, len(fields))
+		}
+		for k, v := range fields {
+			e.fields[k] = v
+		}
+	}
+}
+
+// Fields walks the error chain and returns the structured fields attached by
+// [OptField] and [OptFields], merging outer to inner with outer fields
+// taking precedence
+func Fields(err error) map[string]any {
+	fields := map[string]any{}
+	addFields(err, fields)
+	return fields
+}
+
+func addFields(err error, fields map[string]any) {
+	if err == nil {
+		return
+	}
+	if e, ok := err.(*Error); ok {
+		for k, v := range e.fields {
+			if _, ok := fields[k]; !ok {
+				fields[k] = v
+			}
+		}
+	}
+	switch k := err.(type) {
+	case errorUnwrapper:
+		for _, i := range k.Unwrap() {
+			addFields(i, fields)
+		}
+	case errorSingleUnwrapper:
+		addFields(k.Unwrap(), fields)
+	}
+}
+
+var (
+	// ErrTransient is a sentinel [RetryHint] kind for errors that are safe to
+	// retry or requeue with backoff
+	ErrTransient error = errors.New("Transient error")
+	// ErrPermanent is a sentinel [RetryHint] kind for errors that will never
+	// succeed on retry and should fail fast
+	ErrPermanent error = errors.New("Permanent error")
+)
+
+type (
+	// RetryHint describes how a caller should handle retrying the operation
+	// that produced an error
+	RetryHint struct {
+		// After is how long to wait before retrying
+		After time.Duration
+		// Permanent marks the error as not retryable
+		Permanent bool
+		// Reason is a human readable explanation of the hint
+		Reason string
+	}
+
+	retryJSON struct {
+		After     time.Duration `json:"after,omitempty"`
+		Permanent bool          `json:"permanent,omitempty"`
+		Reason    string        `json:"reason,omitempty"`
+	}
+)
+
+func (h RetryHint) toRetryJSON() retryJSON {
+	return retryJSON{
+		After:     h.After,
+		Permanent: h.Permanent,
+		Reason:    h.Reason,
+	}
+}
+
+func (h RetryHint) logValue() slog.Value {
+	return slog.GroupValue(
+		slog.Duration("after", h.After),
+		slog.Bool("permanent", h.Permanent),
+		slog.String("reason", h.Reason),
+	)
+}
+
+// OptRetry returns an [ErrorOpt] that attaches a [RetryHint] to [Error]
+func OptRetry(hint RetryHint) ErrorOpt {
+	return func(e *Error) {
+		h := hint
+		e.retry = &h
+	}
+}
+
+// RetryOf walks the error chain and returns the innermost [RetryHint]
+// attached by [OptRetry]
+func RetryOf(err error) (RetryHint, bool) {
+	var hint RetryHint
+	found := false
+	addRetryHint(err, &hint, &found)
+	return hint, found
+}
+
+func addRetryHint(err error, hint *RetryHint, found *bool) {
+	if err == nil {
+		return
+	}
+	if e, ok := err.(*Error); ok && e.retry != nil {
+		*hint = *e.retry
+		*found = true
+	}
+	switch k := err.(type) {
+	case errorUnwrapper:
+		for _, i := range k.Unwrap() {
+			addRetryHint(i, hint, found)
+		}
+	case errorSingleUnwrapper:
+		addRetryHint(k.Unwrap(), hint, found)
+	}
+}
+
 type (
 	errorJSON struct {
-		Message string `json:"msg"`
-		Kind    any    `json:"kind,omitempty"`
-		Cause   any    `json:"cause,omitempty"`
+		Message string         `json:"msg"`
+		Kind    any            `json:"kind,omitempty"`
+		Cause   any            `json:"cause,omitempty"`
+		Fields  map[string]any `json:"fields,omitempty"`
+		Retry   any            `json:"retry,omitempty"`
 	}
 )
 
 // JSONErrorValue implements [JSONValuer] and returns a json representation of
 // the error
 func (e *Error) JSONErrorValue() any {
+	var retry any
+	if e.retry != nil {
+		retry = e.retry.toRetryJSON()
+	}
 	return errorJSON{
 		Message: e.Error(),
 		Kind:    JSONValue(e.Kind()),
 		Cause:   JSONValue(e.Cause()),
+		Fields:  e.fields,
+		Retry:   retry,
 	}
 }
 
@@ -186,7 +489,10 @@ func (e *StackTrace) Error() string {
 	if e.n > 0 {
 		frameIter := runtime.CallersFrames(e.pc[:1])
 		f, _ := frameIter.Next()
-		e.writeStackFrame(&b, f)
+		frame := Frame{frame: f}
+		b.WriteString(frame.frame.Function)
+		b.WriteString(" ")
+		fmt.Fprintf(&b, "%v", frame)
 	} else {
 		b.WriteString("empty")
 	}
@@ -194,6 +500,51 @@ func (e *StackTrace) Error() string {
 	return b.String()
 }
 
+// Format implements [fmt.Formatter]. %s and %v print the short message
+// chain, %+v additionally walks the full wrapped chain printing every frame
+// of the stack trace, and %q quotes the short form.
+func (e *StackTrace) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeErrorVerbose(s, e)
+			return
+		}
+		io.WriteString(s, errShortString(e))
+	case 's':
+		io.WriteString(s, errShortString(e))
+	case 'q':
+		fmt.Fprintf(s, "%q", errShortString(e))
+	}
+}
+
+// LogValue implements [slog.LogValuer] and returns a structured
+// representation of the stack trace for structured logging
+func (e *StackTrace) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 3)
+	attrs = append(attrs, slog.String("msg", "Stack trace"))
+	if e.n > 0 {
+		stack := make([]slog.Attr, 0, e.n)
+		n := 0
+		for f := range e.stackIter() {
+			stack = append(stack, slog.Attr{
+				Key: strconv.Itoa(n),
+				Value: slog.GroupValue(
+					slog.String("fn", f.Function),
+					slog.String("file", f.File),
+					slog.Int("line", f.Line),
+				),
+			})
+			n++
+		}
+		attrs = append(attrs, slog.Attr{Key: "stack", Value: slog.GroupValue(stack...)})
+	}
+	if cause := e.Cause(); cause != nil {
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: errSlogValue(cause)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
 // Cause returns the inner wrapped error
 func (e *StackTrace) Cause() error {
 	return e.wrapped
@@ -208,6 +559,16 @@ func (e *StackTrace) PC() []uintptr {
 	return e.pc[:e.n]
 }
 
+// StackTrace returns every frame of the stack trace, resolved and ready for
+// programmatic use
+func (e *StackTrace) StackTrace() []Frame {
+	frames := make([]Frame, 0, e.n)
+	for f := range e.stackIter() {
+		frames = append(frames, Frame{frame: f})
+	}
+	return frames
+}
+
 func (e *StackTrace) stackIter() iter.Seq[runtime.Frame] {
 	if e.n <= 0 {
 		return func(yield func(runtime.Frame) bool) {
@@ -228,26 +589,20 @@ func (e *StackTrace) stackIter() iter.Seq[runtime.Frame] {
 	}
 }
 
-func (e *StackTrace) writeStackFrame(b *strings.Builder, f runtime.Frame) {
-	b.WriteString(f.Function)
-	b.WriteString(" ")
-	b.WriteString(f.File)
-	b.WriteString(":")
-	b.WriteString(strconv.Itoa(f.Line))
-}
-
 // StackString implements [StackStringer] and formats each frame of the stack
 // trace with the default format
 func (e *StackTrace) StackString() string {
 	var b strings.Builder
 	first := true
-	for f := range e.stackIter() {
+	for _, f := range e.StackTrace() {
 		if first {
 			first = false
 		} else {
 			b.WriteString("\n")
 		}
-		e.writeStackFrame(&b, f)
+		b.WriteString(f.frame.Function)
+		b.WriteString(" ")
+		fmt.Fprintf(&b, "%v", f)
 	}
 	return b.String()
 }
@@ -276,19 +631,94 @@ func (e *StackTrace) JSONErrorValue() any {
 	if e.n > 0 {
 		s.Stack = make([]stackFrameJSON, 0, e.n)
 	}
-	for f := range e.stackIter() {
-		s.Stack = append(s.Stack, stackFrameJSON{
-			Function: f.Function,
-			File:     f.File,
-			Line:     f.Line,
-		})
+	for _, f := range e.StackTrace() {
+		s.Stack = append(s.Stack, f.toStackFrameJSON())
 	}
 	return s
 }
 
+type (
+	// Frame is a single resolved stack trace frame
+	Frame struct {
+		frame runtime.Frame
+	}
+)
+
+// Format implements [fmt.Formatter] with the same verbs as pkg/errors:
+// %s the base file name, %d the line number, %n the function name, %v
+// file:line, and %+v the function followed by an indented file:line.
+func (f Frame) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 's':
+		io.WriteString(s, filepath.Base(f.frame.File))
+	case 'd':
+		io.WriteString(s, strconv.Itoa(f.frame.Line))
+	case 'n':
+		io.WriteString(s, frameFuncName(f.frame.Function))
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, f.frame.Function)
+			io.WriteString(s, "\n\t")
+			io.WriteString(s, f.frame.File)
+			io.WriteString(s, ":")
+			io.WriteString(s, strconv.Itoa(f.frame.Line))
+			return
+		}
+		io.WriteString(s, f.frame.File)
+		io.WriteString(s, ":")
+		io.WriteString(s, strconv.Itoa(f.frame.Line))
+	}
+}
+
+// frameFuncName trims a fully qualified function name down to its package
+// local name, e.g. "xorkevin.dev/kerrors.New" becomes "New"
+func frameFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+func (f Frame) toStackFrameJSON() stackFrameJSON {
+	return stackFrameJSON{
+		Function: f.frame.Function,
+		File:     f.frame.File,
+		Line:     f.frame.Line,
+	}
+}
+
+// MarshalJSON implements [json.Marshaler] and marshals to the same shape as
+// [StackTrace.JSONErrorValue]'s stack frames
+func (f Frame) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.toStackFrameJSON())
+}
+
+type (
+	// stackTraceAware is implemented by errors that can report in O(1)
+	// whether a [*StackTrace] is already present in their chain, without
+	// needing a full [Find] traversal
+	stackTraceAware interface {
+		hasStackTrace() bool
+	}
+)
+
+func (e *Error) hasStackTrace() bool {
+	return e.hasStack
+}
+
+func (e *StackTrace) hasStackTrace() bool {
+	return true
+}
+
 // AddStackTrace adds a [*StackTrace] if one is not already present in the
 // error chain
 func AddStackTrace(err error, skip int) error {
+	if k, ok := err.(stackTraceAware); ok && k.hasStackTrace() {
+		return err
+	}
 	if _, ok := Find[*StackTrace](err); ok {
 		return err
 	}
@@ -1,11 +1,15 @@
 package kerrors
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -195,6 +199,30 @@ func TestStackTrace(t *testing.T) {
 		assert.Equal("", st.StackString())
 	})
 
+	t.Run("StackTrace frames", func(t *testing.T) {
+		t.Parallel()
+
+		assert := require.New(t)
+
+		st := NewStackTrace(nil, 0)
+		frames := st.StackTrace()
+		assert.NotEmpty(frames)
+
+		f := frames[0]
+		assert.Equal("kerrors_test.go", fmt.Sprintf("%s", f))
+		assert.True(strings.HasPrefix(fmt.Sprintf("%n", f), "TestStackTrace"))
+		assert.Regexp(regexp.MustCompile(`^\d+$`), fmt.Sprintf("%d", f))
+		assert.Regexp(regexp.MustCompile(`^\S+:\d+$`), fmt.Sprintf("%v", f))
+		assert.Regexp(regexp.MustCompile(`^\S+\n\t\S+:\d+$`), fmt.Sprintf("%+v", f))
+
+		b, err := json.Marshal(frames)
+		assert.NoError(err)
+		var decoded []map[string]any
+		assert.NoError(json.Unmarshal(b, &decoded))
+		assert.Contains(decoded[0]["fn"], "TestStackTrace")
+		assert.Contains(decoded[0]["file"], "xorkevin.dev/kerrors/kerrors_test.go")
+	})
+
 	t.Run("As", func(t *testing.T) {
 		t.Parallel()
 
@@ -241,6 +269,214 @@ func (e *testAsError) As(t any) bool {
 	return false
 }
 
+func TestErrorFormat(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	inner := errors.New("inner error")
+	err := WithKind(WithMsg(inner, "middle message"), testErr{}, "outer message")
+
+	assert.Equal("outer message: middle message: inner error", fmt.Sprintf("%s", err))
+	assert.Equal("outer message: middle message: inner error", fmt.Sprintf("%v", err))
+	assert.Equal(`"outer message: middle message: inner error"`, fmt.Sprintf("%q", err))
+
+	verbose := fmt.Sprintf("%+v", err)
+	assert.Contains(verbose, "outer message")
+	assert.Contains(verbose, "middle message")
+	assert.Contains(verbose, "inner error")
+	assert.Contains(verbose, "Stack trace:")
+	assert.Contains(verbose, "xorkevin.dev/kerrors.TestErrorFormat")
+}
+
+func TestErrorFields(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	inner := New(OptMsg("inner message"), OptField("reqid", "abc"), OptField("shared", "inner"))
+	outer := New(OptMsg("outer message"), OptCause(inner), OptFields(map[string]any{
+		"userid": "123",
+		"shared": "outer",
+	}))
+
+	fields := Fields(outer)
+	assert.Equal(map[string]any{
+		"reqid":  "abc",
+		"userid": "123",
+		"shared": "outer",
+	}, fields)
+
+	b, err := json.Marshal(JSONValue(outer))
+	assert.NoError(err)
+	var tree map[string]any
+	assert.NoError(json.Unmarshal(b, &tree))
+	assert.Equal(map[string]any{"userid": "123", "shared": "outer"}, tree["fields"])
+	cause, ok := tree["cause"].(map[string]any)
+	assert.True(ok)
+	assert.Equal(map[string]any{"reqid": "abc", "shared": "inner"}, cause["fields"])
+}
+
+func TestErrorLogValue(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	errorsErr := errors.New("test errors err")
+	err := WithKind(WithMsg(errorsErr, "another message"), testErr{}, "test error message")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("failed", KErrorAttr(err))
+
+	var logLine map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &logLine))
+	logged, ok := logLine["err"].(map[string]any)
+	assert.True(ok)
+
+	b, jerr := json.Marshal(JSONValue(err))
+	assert.NoError(jerr)
+	var jsonTree map[string]any
+	assert.NoError(json.Unmarshal(b, &jsonTree))
+
+	assert.Equal(jsonTree["msg"], logged["msg"])
+	assert.Equal(jsonTree["kind"], logged["kind"])
+	cause, ok := jsonTree["cause"].(map[string]any)
+	assert.True(ok)
+	loggedCause, ok := logged["cause"].(map[string]any)
+	assert.True(ok)
+	assert.Equal(cause["msg"], loggedCause["msg"])
+
+	loggedStackTrace, ok := loggedCause["cause"].(map[string]any)
+	assert.True(ok)
+	assert.Equal("Stack trace", loggedStackTrace["msg"])
+	loggedStack, ok := loggedStackTrace["stack"].(map[string]any)
+	assert.True(ok)
+	frame0, ok := loggedStack["0"].(map[string]any)
+	assert.True(ok)
+	assert.Contains(frame0["fn"], "xorkevin.dev/kerrors.TestErrorLogValue")
+	assert.Contains(frame0["file"], "xorkevin.dev/kerrors/kerrors_test.go")
+	assert.NotZero(frame0["line"])
+}
+
+type testMultiUnwrapError struct {
+	wrapped []error
+}
+
+func (e *testMultiUnwrapError) Error() string {
+	return "Test multi unwrap error"
+}
+
+func (e *testMultiUnwrapError) Unwrap() []error {
+	return e.wrapped
+}
+
+func TestErrSlogValueMultiCause(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	err := &testMultiUnwrapError{wrapped: []error{
+		errors.New("first cause"),
+		errors.New("second cause"),
+	}}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("failed", KErrorAttr(err))
+
+	var logLine map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &logLine))
+	logged, ok := logLine["err"].(map[string]any)
+	assert.True(ok)
+	cause, ok := logged["cause"].(map[string]any)
+	assert.True(ok)
+	assert.Equal("first cause", cause["0"])
+	assert.Equal("second cause", cause["1"])
+}
+
+func countStackTraces(err error) int {
+	if err == nil {
+		return 0
+	}
+	n := 0
+	if _, ok := err.(*StackTrace); ok {
+		n++
+	}
+	switch k := err.(type) {
+	case errorUnwrapper:
+		for _, i := range k.Unwrap() {
+			n += countStackTraces(i)
+		}
+	case errorSingleUnwrapper:
+		n += countStackTraces(k.Unwrap())
+	}
+	return n
+}
+
+func TestAddStackTraceDedup(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	err := New(OptMsg("base"))
+	for i := 0; i < 10; i++ {
+		err = WithMsg(err, "wrap")
+	}
+
+	assert.Equal(1, countStackTraces(err))
+}
+
+func BenchmarkAddStackTrace(b *testing.B) {
+	for _, depth := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("depth%d", depth), func(b *testing.B) {
+			err := error(errors.New("base"))
+			for range depth {
+				err = WithMsg(err, "wrap")
+			}
+
+			b.ResetTimer()
+			for range b.N {
+				_ = WithMsg(err, "bench wrap")
+			}
+		})
+	}
+}
+
+func TestRetryHint(t *testing.T) {
+	t.Parallel()
+
+	assert := require.New(t)
+
+	inner := WithKind(New(OptMsg("transient failure"), OptRetry(RetryHint{
+		After:  time.Second,
+		Reason: "rate limited",
+	})), ErrTransient, "request failed")
+	outer := WithMsg(WithMsg(inner, "middle"), "outer")
+
+	assert.ErrorIs(outer, ErrTransient)
+	assert.NotErrorIs(outer, ErrPermanent)
+
+	hint, ok := RetryOf(outer)
+	assert.True(ok)
+	assert.Equal(time.Second, hint.After)
+	assert.False(hint.Permanent)
+	assert.Equal("rate limited", hint.Reason)
+
+	_, ok = RetryOf(errors.New("no hint here"))
+	assert.False(ok)
+
+	b, err := json.Marshal(JSONValue(inner))
+	assert.NoError(err)
+	var tree map[string]any
+	assert.NoError(json.Unmarshal(b, &tree))
+	cause, ok := tree["cause"].(map[string]any)
+	assert.True(ok)
+	retry, ok := cause["retry"].(map[string]any)
+	assert.True(ok)
+	assert.Equal("rate limited", retry["reason"])
+}
+
 func TestFind(t *testing.T) {
 	t.Parallel()
 